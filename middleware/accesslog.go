@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/markoczy/webapi"
+)
+
+// AccessLog returns a HandlerFunc that logs method, path, response
+// status, bytes written and duration for every request through logger,
+// once the rest of the chain has finished handling it.
+func AccessLog(logger *log.Logger) webapi.HandlerFunc {
+	return func(w http.ResponseWriter, r *webapi.ParsedRequest, next func() webapi.Handler) webapi.Handler {
+		start := time.Now()
+		sw := newStatusResponseWriter(w)
+
+		if n := next(); n != nil {
+			n.HandleAll(sw, r)
+		}
+
+		req := r.Request()
+		logger.Printf("%s %s %d %dB %s", req.Method, req.URL.Path, sw.Status(), sw.BytesWritten(), time.Since(start))
+		return nil
+	}
+}
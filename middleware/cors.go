@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/markoczy/webapi"
+)
+
+// CORSOptions configures CORS. A zero-value CORSOptions allows no
+// cross-origin requests; set AllowedOrigins to "*" to allow all of them.
+type CORSOptions struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	MaxAge           int
+}
+
+func (opts CORSOptions) allowOrigin(origin string) bool {
+	for _, allowed := range opts.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// CORS returns a HandlerFunc that applies the Cross-Origin Resource
+// Sharing headers described by opts and short-circuits CORS preflight
+// (OPTIONS) requests from allowed origins with a 204 response instead of
+// passing them to the rest of the chain. Requests without an Origin
+// header or from an origin that is not allowed are passed on unchanged.
+func CORS(opts CORSOptions) webapi.HandlerFunc {
+	methods := strings.Join(opts.AllowedMethods, ", ")
+	headers := strings.Join(opts.AllowedHeaders, ", ")
+
+	return func(w http.ResponseWriter, r *webapi.ParsedRequest, next func() webapi.Handler) webapi.Handler {
+		req := r.Request()
+		origin := req.Header.Get("Origin")
+		if origin == "" || !opts.allowOrigin(origin) {
+			return next()
+		}
+
+		h := w.Header()
+		if len(opts.AllowedOrigins) == 1 && opts.AllowedOrigins[0] == "*" && !opts.AllowCredentials {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Add("Vary", "Origin")
+		}
+		if opts.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if req.Method != http.MethodOptions || req.Header.Get("Access-Control-Request-Method") == "" {
+			return next()
+		}
+
+		// preflight request
+		if methods != "" {
+			h.Set("Access-Control-Allow-Methods", methods)
+		}
+		if headers != "" {
+			h.Set("Access-Control-Allow-Headers", headers)
+		}
+		if opts.MaxAge > 0 {
+			h.Set("Access-Control-Max-Age", strconv.Itoa(opts.MaxAge))
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+
+	"github.com/markoczy/webapi"
+)
+
+// gzipResponseWriter wraps an http.ResponseWriter so that everything
+// written to it is compressed through a gzip.Writer instead. It strips
+// any Content-Length a handler set, since that length describes the
+// uncompressed body and would otherwise make net/http truncate the
+// (shorter) compressed response.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	wroteHeader bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	w.Header().Del("Content-Length")
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.Header().Del("Content-Length")
+	}
+	return w.gz.Write(data)
+}
+
+// Gzip returns a HandlerFunc that transparently compresses the response
+// body with gzip whenever the client's Accept-Encoding header allows it,
+// using the given compression level (see compress/gzip for valid
+// values, e.g. gzip.DefaultCompression).
+func Gzip(level int) webapi.HandlerFunc {
+	return func(w http.ResponseWriter, r *webapi.ParsedRequest, next func() webapi.Handler) webapi.Handler {
+		if !strings.Contains(r.Request().Header.Get("Accept-Encoding"), "gzip") {
+			return next()
+		}
+
+		gz, err := gzip.NewWriterLevel(w, level)
+		if err != nil {
+			return next()
+		}
+		defer gz.Close()
+
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		if n := next(); n != nil {
+			n.HandleAll(&gzipResponseWriter{ResponseWriter: w, gz: gz}, r)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/markoczy/webapi"
+)
+
+func newTestRouter(handler webapi.Handler) *webapi.Router {
+	router := webapi.NewRouter(webapi.NewErrorHandler(http.StatusNotFound, "404 not found"))
+	router.Handle(http.MethodGet, "/", handler)
+	return router
+}
+
+func TestRecovery(t *testing.T) {
+	log.Println("Test Recovery")
+	panicHandler := webapi.NewHandler(Recovery(), func(w http.ResponseWriter, r *webapi.ParsedRequest, next func() webapi.Handler) webapi.Handler {
+		panic("boom")
+	})
+
+	router := newTestRouter(panicHandler)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	have, want := w.Code, http.StatusInternalServerError
+	if have != want {
+		t.Errorf("have status %d want %d", have, want)
+	}
+}
+
+func TestGzip(t *testing.T) {
+	log.Println("Test Gzip")
+	mockData := "hello, gzip"
+	handler := webapi.NewHandler(Gzip(gzip.DefaultCompression), func(w http.ResponseWriter, r *webapi.ParsedRequest, next func() webapi.Handler) webapi.Handler {
+		w.Write([]byte(mockData))
+		return next()
+	})
+
+	router := newTestRouter(handler)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if enc := w.Header().Get("Content-Encoding"); enc != "gzip" {
+		t.Fatalf("have Content-Encoding %q want %q", enc, "gzip")
+	}
+
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("response body is not valid gzip: %v", err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("failed reading gzip body: %v", err)
+	}
+	if have, want := string(data), mockData; have != want {
+		t.Errorf("have body %q want %q", have, want)
+	}
+}
+
+func TestGzipStripsDownstreamContentLength(t *testing.T) {
+	log.Println("Test Gzip Strips Downstream Content Length")
+	mockData := "hello, gzip"
+	handler := webapi.NewHandler(Gzip(gzip.DefaultCompression), func(w http.ResponseWriter, r *webapi.ParsedRequest, next func() webapi.Handler) webapi.Handler {
+		w.Header().Set("Content-Length", strconv.Itoa(len(mockData)))
+		w.Write([]byte(mockData))
+		return next()
+	})
+
+	router := newTestRouter(handler)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if cl := w.Header().Get("Content-Length"); cl != "" {
+		t.Fatalf("Content-Length should have been stripped, got %q", cl)
+	}
+
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("response body is not valid gzip: %v", err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("failed reading gzip body: %v", err)
+	}
+	if have, want := string(data), mockData; have != want {
+		t.Errorf("have body %q want %q", have, want)
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	log.Println("Test CORS Preflight")
+	handler := webapi.NewHandler(CORS(CORSOptions{
+		AllowedOrigins: []string{"https://example.com"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost},
+	}), func(w http.ResponseWriter, r *webapi.ParsedRequest, next func() webapi.Handler) webapi.Handler {
+		t.Fatal("preflight request should not reach the application handler")
+		return next()
+	})
+
+	router := newTestRouter(handler)
+	router.Handle(http.MethodOptions, "/", handler)
+	req := httptest.NewRequest(http.MethodOptions, "/", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if have, want := w.Code, http.StatusNoContent; have != want {
+		t.Errorf("have status %d want %d", have, want)
+	}
+	if have, want := w.Header().Get("Access-Control-Allow-Origin"), "https://example.com"; have != want {
+		t.Errorf("have Allow-Origin %q want %q", have, want)
+	}
+	if have, want := w.Header().Get("Access-Control-Allow-Methods"), "GET, POST"; have != want {
+		t.Errorf("have Allow-Methods %q want %q", have, want)
+	}
+}
+
+func TestRealIP(t *testing.T) {
+	log.Println("Test Real IP")
+	var seenRemoteAddr string
+	handler := webapi.NewHandler(RealIP("10.0.0.0/8"), func(w http.ResponseWriter, r *webapi.ParsedRequest, next func() webapi.Handler) webapi.Handler {
+		seenRemoteAddr = r.Request().RemoteAddr
+		return next()
+	})
+
+	router := newTestRouter(handler)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.1.2.3:5555"
+	req.Header.Set("X-Forwarded-For", "203.0.113.9, 10.1.2.3")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if have, want := seenRemoteAddr, "203.0.113.9"; have != want {
+		t.Errorf("have RemoteAddr %q want %q", have, want)
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	log.Println("Test Access Log")
+	var buf bytes.Buffer
+	logger := log.New(&buf, "", 0)
+
+	handler := webapi.NewHandler(AccessLog(logger), func(w http.ResponseWriter, r *webapi.ParsedRequest, next func() webapi.Handler) webapi.Handler {
+		w.Write([]byte("ok"))
+		return next()
+	})
+
+	router := newTestRouter(handler)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	logged := buf.String()
+	if !bytes.Contains(buf.Bytes(), []byte("GET /")) {
+		t.Errorf("access log missing method/path, got %q", logged)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("200")) {
+		t.Errorf("access log missing status, got %q", logged)
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/markoczy/webapi"
+)
+
+// RealIP returns a HandlerFunc that rewrites the request's RemoteAddr
+// from the X-Forwarded-For or X-Real-IP headers, but only when the
+// connecting peer's address falls within one of the given trusted proxy
+// CIDR ranges, to avoid clients spoofing their own IP. trustedProxies
+// entries that fail to parse as a CIDR are ignored. It panics if none of
+// the given entries parse, since that almost certainly means the
+// middleware would silently never trust anything.
+func RealIP(trustedProxies ...string) webapi.HandlerFunc {
+	nets := make([]*net.IPNet, 0, len(trustedProxies))
+	for _, cidr := range trustedProxies {
+		if _, ipNet, err := net.ParseCIDR(cidr); err == nil {
+			nets = append(nets, ipNet)
+		}
+	}
+	if len(trustedProxies) > 0 && len(nets) == 0 {
+		panic("webapi/middleware: RealIP: no trusted proxy CIDR could be parsed")
+	}
+
+	return func(w http.ResponseWriter, r *webapi.ParsedRequest, next func() webapi.Handler) webapi.Handler {
+		req := r.Request()
+		host, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			return next()
+		}
+
+		remote := net.ParseIP(host)
+		if remote == nil || !trusted(nets, remote) {
+			return next()
+		}
+
+		if ip := firstForwardedIP(req); ip != "" {
+			req.RemoteAddr = ip
+		}
+		return next()
+	}
+}
+
+func trusted(nets []*net.IPNet, ip net.IP) bool {
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func firstForwardedIP(r *http.Request) string {
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		parts := strings.Split(xff, ",")
+		return strings.TrimSpace(parts[0])
+	}
+	return r.Header.Get("X-Real-IP")
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/markoczy/webapi"
+)
+
+// Recovery returns a HandlerFunc that runs the remainder of the chain
+// under recover(), logging the panic and stack trace and responding with
+// a 500 Internal Server Error instead of letting the panic reach the
+// net/http server.
+func Recovery() webapi.HandlerFunc {
+	return func(w http.ResponseWriter, r *webapi.ParsedRequest, next func() webapi.Handler) (result webapi.Handler) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				log.Printf("webapi: panic recovered: %v\n%s", rec, debug.Stack())
+				webapi.NewErrorHandler(http.StatusInternalServerError, "500 internal server error").HandleAll(w, r)
+				result = nil
+			}
+		}()
+
+		if n := next(); n != nil {
+			n.HandleAll(w, r)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,42 @@
+// Package middleware provides ready-made webapi.HandlerFunc values for
+// common cross-cutting concerns: panic recovery, gzip compression, CORS,
+// client IP resolution and access logging. Each one is plugged in via
+// webapi.NewHandler(middleware..., appHandler) or Router.Group.
+package middleware
+
+import "net/http"
+
+// statusResponseWriter wraps an http.ResponseWriter to record the status
+// code and number of bytes written, for middleware that needs to report
+// on the response after the rest of the handler chain has run.
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status  int
+	written int
+}
+
+func newStatusResponseWriter(w http.ResponseWriter) *statusResponseWriter {
+	return &statusResponseWriter{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (w *statusResponseWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusResponseWriter) Write(data []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(data)
+	w.written += n
+	return n, err
+}
+
+// Status returns the status code that was written, or http.StatusOK if
+// WriteHeader was never called.
+func (w *statusResponseWriter) Status() int {
+	return w.status
+}
+
+// BytesWritten returns the number of response body bytes written so far.
+func (w *statusResponseWriter) BytesWritten() int {
+	return w.written
+}
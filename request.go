@@ -0,0 +1,219 @@
+package webapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// ParamError is returned by ParamInt, ParamUUID and QueryInt when the
+// requested path or query value cannot be converted to the requested
+// type. Its Status is always http.StatusBadRequest, so it maps directly
+// onto NewErrorHandler(err.Status, err.Error()).
+type ParamError struct {
+	Status int
+	Name   string
+	Err    error
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("invalid value for %q: %v", e.Name, e.Err)
+}
+
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError is returned by DecodeJSON and DecodeForm when the request
+// body cannot be read or parsed. Its Status is always
+// http.StatusBadRequest, so it maps directly onto
+// NewErrorHandler(err.Status, err.Error()).
+type DecodeError struct {
+	Status int
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Param returns the value of the named path parameter, or an empty
+// string if the matched route does not capture a parameter by that name.
+func (r *ParsedRequest) Param(name string) string {
+	return r.pathParams[name]
+}
+
+// ParamInt returns the named path parameter parsed as an int.
+func (r *ParsedRequest) ParamInt(name string) (int, error) {
+	v, err := strconv.Atoi(r.Param(name))
+	if err != nil {
+		return 0, &ParamError{Status: http.StatusBadRequest, Name: name, Err: err}
+	}
+	return v, nil
+}
+
+// ParamUUID returns the named path parameter parsed as a uuid.UUID.
+func (r *ParsedRequest) ParamUUID(name string) (uuid.UUID, error) {
+	id, err := uuid.Parse(r.Param(name))
+	if err != nil {
+		return uuid.UUID{}, &ParamError{Status: http.StatusBadRequest, Name: name, Err: err}
+	}
+	return id, nil
+}
+
+// query lazily parses and caches r.request.URL.Query().
+func (r *ParsedRequest) query() url.Values {
+	if r.queryValues == nil {
+		r.queryValues = r.request.URL.Query()
+	}
+	return r.queryValues
+}
+
+// Query returns the named query string parameter, or an empty string if
+// it is not present.
+func (r *ParsedRequest) Query(name string) string {
+	return r.query().Get(name)
+}
+
+// QueryDefault returns the named query string parameter, or def if it is
+// not present.
+func (r *ParsedRequest) QueryDefault(name, def string) string {
+	if !r.query().Has(name) {
+		return def
+	}
+	return r.query().Get(name)
+}
+
+// QueryInt returns the named query string parameter parsed as an int.
+func (r *ParsedRequest) QueryInt(name string) (int, error) {
+	v, err := strconv.Atoi(r.Query(name))
+	if err != nil {
+		return 0, &ParamError{Status: http.StatusBadRequest, Name: name, Err: err}
+	}
+	return v, nil
+}
+
+// Form lazily parses (and caches) the request's form body via
+// r.request.ParseForm, then returns the named field, checking the body
+// first and falling back to the query string, matching the semantics of
+// http.Request.FormValue.
+func (r *ParsedRequest) Form(name string) string {
+	if !r.formParsed {
+		r.request.ParseForm()
+		r.formParsed = true
+	}
+	return r.request.Form.Get(name)
+}
+
+// Context returns the request's context, as http.Request.Context.
+func (r *ParsedRequest) Context() context.Context {
+	return r.request.Context()
+}
+
+// WithValue attaches val under key to the request's context, so that
+// handlers further down the chain can read it back via Context(). It is
+// typically used by middleware to pass along an auth principal, request
+// id, or similar cross-cutting value.
+func (r *ParsedRequest) WithValue(key, val interface{}) {
+	r.request = r.request.WithContext(context.WithValue(r.request.Context(), key, val))
+}
+
+// DecodeJSON reads the request body, up to MaxBodyBytes if set, and
+// decodes it as JSON into v.
+func (r *ParsedRequest) DecodeJSON(v interface{}) error {
+	body := r.request.Body
+	if r.MaxBodyBytes > 0 {
+		body = http.MaxBytesReader(nil, body, r.MaxBodyBytes)
+	}
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		return &DecodeError{Status: http.StatusBadRequest, Err: err}
+	}
+	return nil
+}
+
+// DecodeForm reads the request's form body, up to MaxBodyBytes if set,
+// and decodes its values, merged with the URL query values as
+// http.Request.ParseForm does, into the fields of the struct pointed to
+// by v.
+// Fields are matched by a `form:"name"` tag, falling back to the field
+// name itself; only string, bool, int and float kinds are supported.
+func (r *ParsedRequest) DecodeForm(v interface{}) error {
+	if r.MaxBodyBytes > 0 && r.request.Body != nil {
+		r.request.Body = http.MaxBytesReader(nil, r.request.Body, r.MaxBodyBytes)
+	}
+	if !r.formParsed {
+		if err := r.request.ParseForm(); err != nil {
+			return &DecodeError{Status: http.StatusBadRequest, Err: err}
+		}
+		r.formParsed = true
+	}
+
+	if err := decodeFormValues(r.request.Form, v); err != nil {
+		return &DecodeError{Status: http.StatusBadRequest, Err: err}
+	}
+	return nil
+}
+
+// decodeFormValues copies values into the exported fields of the struct
+// pointed to by v, matched by `form` tag or field name.
+func decodeFormValues(values url.Values, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("webapi: DecodeForm requires a pointer to a struct, got %T", v)
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+
+		name := field.Tag.Get("form")
+		if name == "" {
+			name = field.Name
+		}
+		if !values.Has(name) {
+			continue
+		}
+		raw := values.Get(name)
+
+		fv := rv.Field(i)
+		switch fv.Kind() {
+		case reflect.String:
+			fv.SetString(raw)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				return fmt.Errorf("field %q: %w", name, err)
+			}
+			fv.SetBool(b)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return fmt.Errorf("field %q: %w", name, err)
+			}
+			fv.SetInt(n)
+		case reflect.Float32, reflect.Float64:
+			f, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return fmt.Errorf("field %q: %w", name, err)
+			}
+			fv.SetFloat(f)
+		default:
+			return fmt.Errorf("field %q: unsupported kind %s", name, fv.Kind())
+		}
+	}
+	return nil
+}
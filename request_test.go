@@ -0,0 +1,138 @@
+package webapi
+
+import (
+	"io"
+	"log"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestParsedRequestParams(t *testing.T) {
+	log.Println("Test Parsed Request Params")
+	id := uuid.New()
+	r := newMockRequest(func(req *ParsedRequest) {
+		req.pathParams = map[string]string{"id": id.String(), "n": "42", "bad": "nope"}
+	})
+
+	if have, want := r.Param("id"), id.String(); have != want {
+		t.Errorf("Param: have %s want %s", have, want)
+	}
+
+	if have, err := r.ParamInt("n"); err != nil || have != 42 {
+		t.Errorf("ParamInt: have (%d, %v) want (42, nil)", have, err)
+	}
+
+	if _, err := r.ParamInt("bad"); err == nil {
+		t.Errorf("ParamInt: expected error for non-numeric param")
+	}
+
+	if have, err := r.ParamUUID("id"); err != nil || have != id {
+		t.Errorf("ParamUUID: have (%v, %v) want (%v, nil)", have, err, id)
+	}
+
+	if _, err := r.ParamUUID("bad"); err == nil {
+		t.Errorf("ParamUUID: expected error for non-uuid param")
+	}
+}
+
+func TestParsedRequestQuery(t *testing.T) {
+	log.Println("Test Parsed Request Query")
+	r := newMockRequest(func(req *ParsedRequest) {
+		req.request.URL = &url.URL{RawQuery: "name=gopher&age=10"}
+	})
+
+	if have, want := r.Query("name"), "gopher"; have != want {
+		t.Errorf("Query: have %s want %s", have, want)
+	}
+
+	if have, err := r.QueryInt("age"); err != nil || have != 10 {
+		t.Errorf("QueryInt: have (%d, %v) want (10, nil)", have, err)
+	}
+
+	if have, want := r.QueryDefault("missing", "fallback"), "fallback"; have != want {
+		t.Errorf("QueryDefault: have %s want %s", have, want)
+	}
+
+	if have, want := r.QueryDefault("name", "fallback"), "gopher"; have != want {
+		t.Errorf("QueryDefault: have %s want %s", have, want)
+	}
+}
+
+func TestParsedRequestDecodeJSON(t *testing.T) {
+	log.Println("Test Parsed Request Decode JSON")
+	type payload struct {
+		Name string `json:"name"`
+	}
+
+	r := newMockRequest(func(req *ParsedRequest) {
+		req.request.Body = io.NopCloser(strings.NewReader(`{"name":"gopher"}`))
+	})
+
+	var p payload
+	if err := r.DecodeJSON(&p); err != nil {
+		t.Fatalf("DecodeJSON: unexpected error: %v", err)
+	}
+	if have, want := p.Name, "gopher"; have != want {
+		t.Errorf("DecodeJSON: have %s want %s", have, want)
+	}
+}
+
+func TestParsedRequestDecodeJSONError(t *testing.T) {
+	log.Println("Test Parsed Request Decode JSON Error")
+	r := newMockRequest(func(req *ParsedRequest) {
+		req.request.Body = io.NopCloser(strings.NewReader(`not json`))
+	})
+
+	var v map[string]string
+	err := r.DecodeJSON(&v)
+	if err == nil {
+		t.Fatal("DecodeJSON: expected error for invalid JSON")
+	}
+
+	decodeErr, ok := err.(*DecodeError)
+	if !ok {
+		t.Fatalf("DecodeJSON: expected *DecodeError, got %T", err)
+	}
+	if decodeErr.Status != 400 {
+		t.Errorf("DecodeJSON: have status %d want 400", decodeErr.Status)
+	}
+}
+
+func TestParsedRequestDecodeForm(t *testing.T) {
+	log.Println("Test Parsed Request Decode Form")
+	type payload struct {
+		Name string `form:"name"`
+		Age  int    `form:"age"`
+	}
+
+	r := newMockRequest(func(req *ParsedRequest) {
+		req.request.URL = &url.URL{RawQuery: "name=gopher&age=10"}
+		req.request.Method = "GET"
+	})
+
+	var p payload
+	if err := r.DecodeForm(&p); err != nil {
+		t.Fatalf("DecodeForm: unexpected error: %v", err)
+	}
+	if have, want := p.Name, "gopher"; have != want {
+		t.Errorf("DecodeForm: have name %s want %s", have, want)
+	}
+	if have, want := p.Age, 10; have != want {
+		t.Errorf("DecodeForm: have age %d want %d", have, want)
+	}
+}
+
+func TestParsedRequestContextValue(t *testing.T) {
+	log.Println("Test Parsed Request Context Value")
+	type ctxKey string
+	key := ctxKey("requestID")
+	r := newMockRequest()
+
+	r.WithValue(key, "abc-123")
+	if have, want := r.Context().Value(key), "abc-123"; have != want {
+		t.Errorf("Context: have %v want %v", have, want)
+	}
+}
@@ -0,0 +1,159 @@
+package webapi
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Server wraps http.Server with a batteries-included, signal-driven
+// graceful shutdown and optional automatic TLS certificates via Let's
+// Encrypt, dispatching requests to a *Router.
+type Server struct {
+	// ShutdownTimeout bounds how long a graceful shutdown waits for
+	// in-flight requests to finish before giving up. Zero means wait
+	// indefinitely.
+	ShutdownTimeout time.Duration
+	// RedirectHTTP, when true, additionally starts a listener on :80
+	// that redirects every request to its HTTPS equivalent. It only
+	// applies to ListenAndServeTLS; ListenAutocert always redirects on
+	// :80 since it also needs that port for the ACME HTTP-01 challenge.
+	RedirectHTTP bool
+
+	router     *Router
+	onShutdown []func(context.Context) error
+
+	// redirectAddr overrides the bind address used for the HTTP->HTTPS
+	// redirect listener started when RedirectHTTP is set. Tests set this
+	// to an unprivileged loopback address to exercise the redirect
+	// listener without binding the real :80 port; zero value means ":80".
+	redirectAddr string
+}
+
+// NewServer creates a Server that dispatches requests to router.
+func NewServer(router *Router) *Server {
+	return &Server{router: router}
+}
+
+// OnShutdown registers fn to run, in call order, during a graceful
+// shutdown, after the server has stopped accepting new connections -
+// e.g. to close database pools. An error from a callback does not stop
+// later callbacks from running; the first error from the shutdown or a
+// callback is the one returned, and later ones are discarded.
+func (s *Server) OnShutdown(fn func(context.Context) error) {
+	s.onShutdown = append(s.onShutdown, fn)
+}
+
+// ListenAndServe starts serving plain HTTP on addr and blocks until the
+// server receives SIGINT or SIGTERM, at which point it drains in-flight
+// requests and returns. HTTP/2 is available automatically once TLS is
+// used, per the net/http defaults.
+func (s *Server) ListenAndServe(addr string) error {
+	srv := &http.Server{Addr: addr, Handler: s.router}
+	return s.serve(srv, srv.ListenAndServe, nil)
+}
+
+// ListenAndServeTLS starts serving HTTPS on addr using the given
+// certificate and key files, and blocks until the server receives
+// SIGINT or SIGTERM.
+func (s *Server) ListenAndServeTLS(addr, certFile, keyFile string) error {
+	srv := &http.Server{Addr: addr, Handler: s.router}
+
+	var redirect http.Handler
+	if s.RedirectHTTP {
+		redirect = http.HandlerFunc(redirectToHTTPS)
+	}
+	return s.serve(srv, func() error {
+		return srv.ListenAndServeTLS(certFile, keyFile)
+	}, redirect)
+}
+
+// ListenAutocert starts serving HTTPS on :443 using certificates
+// obtained and renewed automatically from Let's Encrypt for the given
+// hostnames, via golang.org/x/crypto/acme/autocert. It also starts a
+// listener on :80 to answer ACME HTTP-01 challenges and to redirect
+// everything else to HTTPS. It blocks until the server receives SIGINT
+// or SIGTERM.
+func (s *Server) ListenAutocert(hostnames ...string) error {
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(hostnames...),
+		Cache:      autocert.DirCache("certs"),
+	}
+	srv := &http.Server{
+		Addr:      ":443",
+		Handler:   s.router,
+		TLSConfig: manager.TLSConfig(),
+	}
+	return s.serve(srv, func() error {
+		return srv.ListenAndServeTLS("", "")
+	}, manager.HTTPHandler(nil))
+}
+
+// serve runs listen in a goroutine and blocks until either it returns,
+// or SIGINT/SIGTERM is received, in which case srv (and the :80 listener
+// behind altPort80, if any) are shut down gracefully.
+func (s *Server) serve(srv *http.Server, listen func() error, altPort80 http.Handler) error {
+	var redirectSrv *http.Server
+	if altPort80 != nil {
+		addr := s.redirectAddr
+		if addr == "" {
+			addr = ":80"
+		}
+		redirectSrv = &http.Server{Addr: addr, Handler: altPort80}
+		go redirectSrv.ListenAndServe()
+		// Ensures the :80 listener is torn down on every return path,
+		// including when listen() fails before a shutdown signal ever
+		// arrives.
+		defer redirectSrv.Close()
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		if err := listen(); err != nil && err != http.ErrServerClosed {
+			errc <- err
+			return
+		}
+		errc <- nil
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	select {
+	case err := <-errc:
+		return err
+	case <-stop:
+	}
+
+	ctx := context.Background()
+	if s.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.ShutdownTimeout)
+		defer cancel()
+	}
+
+	err := srv.Shutdown(ctx)
+	if redirectSrv != nil {
+		redirectSrv.Shutdown(ctx)
+	}
+	for _, fn := range s.onShutdown {
+		if shutdownErr := fn(ctx); shutdownErr != nil && err == nil {
+			err = shutdownErr
+		}
+	}
+
+	<-errc
+	return err
+}
+
+func redirectToHTTPS(w http.ResponseWriter, r *http.Request) {
+	target := "https://" + r.Host + r.URL.RequestURI()
+	http.Redirect(w, r, target, http.StatusMovedPermanently)
+}
@@ -0,0 +1,91 @@
+package webapi
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestServerGracefulShutdown(t *testing.T) {
+	log.Println("Test Server Graceful Shutdown")
+	fallback404 := NewErrorHandler(http.StatusNotFound, "404 not found")
+	router := NewRouter(fallback404)
+
+	server := NewServer(router)
+	server.ShutdownTimeout = 5 * time.Second
+
+	shutdownCalled := make(chan struct{}, 1)
+	server.OnShutdown(func(ctx context.Context) error {
+		shutdownCalled <- struct{}{}
+		return nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.ListenAndServe("127.0.0.1:0")
+	}()
+
+	// give the listener goroutine a moment to start before signalling it
+	time.Sleep(50 * time.Millisecond)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("failed to send SIGTERM: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("ListenAndServe returned error after shutdown: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("server did not shut down within 2s of SIGTERM")
+	}
+
+	select {
+	case <-shutdownCalled:
+	default:
+		t.Error("OnShutdown callback was not called")
+	}
+}
+
+// TestServerRedirectListenerClosedOnListenError ensures that when the
+// primary listen() call fails before any shutdown signal arrives, the
+// redirect listener started for RedirectHTTP is still torn down instead
+// of leaking forever. It stands in an unprivileged loopback address for
+// the real :80 redirect port via the unexported redirectAddr field, so
+// the test doesn't depend on a privileged bind succeeding.
+func TestServerRedirectListenerClosedOnListenError(t *testing.T) {
+	fallback404 := NewErrorHandler(http.StatusNotFound, "404 not found")
+	router := NewRouter(fallback404)
+
+	// Find a free, unprivileged port to stand in for :80.
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	redirectAddr := probe.Addr().String()
+	probe.Close()
+
+	server := NewServer(router)
+	server.RedirectHTTP = true
+	server.redirectAddr = redirectAddr
+
+	// Bogus cert/key paths make ListenAndServeTLS fail immediately, before
+	// a shutdown signal is ever sent.
+	err = server.ListenAndServeTLS("127.0.0.1:0", "does-not-exist.crt", "does-not-exist.key")
+	if err == nil {
+		t.Fatal("expected ListenAndServeTLS to fail with bogus cert paths")
+	}
+
+	// If the redirect listener from RedirectHTTP were still running, this
+	// would fail with "address already in use".
+	ln, err := net.Listen("tcp", redirectAddr)
+	if err != nil {
+		t.Fatalf("redirect listener was not closed after serve() returned: %v", err)
+	}
+	ln.Close()
+}
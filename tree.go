@@ -0,0 +1,405 @@
+package webapi
+
+// The radix tree in this file (node and its methods, plus the
+// longestCommonPrefix/findWildcard helpers) is a derivative of the tree
+// implementation in github.com/julienschmidt/httprouter, copyright (c)
+// 2013 Julien Schmidt, used under the following BSD-3-Clause license:
+//
+// Copyright (c) 2013, Julien Schmidt
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in the
+//     documentation and/or other materials provided with the distribution.
+//   * Neither the name of the author nor the names of its contributors
+//     may be used to endorse or promote products derived from this
+//     software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS
+// IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED
+// TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+// nodeType identifies what kind of path segment a tree node represents.
+type nodeType uint8
+
+const (
+	static nodeType = iota
+	param
+	catchAll
+)
+
+// Param is a single URL path parameter, consisting of a key and a value.
+type Param struct {
+	Key   string
+	Value string
+}
+
+// Params is a slice of Param, populated by the router while walking the
+// tree for a request path. Params.ByName is the preferred way to read a
+// named path parameter.
+type Params []Param
+
+// ByName returns the value of the first Param whose key matches name, or
+// an empty string if name was not captured for the matched route.
+func (ps Params) ByName(name string) string {
+	for _, p := range ps {
+		if p.Key == name {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// node is a single node of the router's radix tree. Each node holds a
+// fragment of one or more registered paths plus its child edges, in the
+// style of julienschmidt/httprouter: static children are indexed by their
+// first byte in indices, while a wildcard child (:param or *catchAll), of
+// which at most one may exist per node, is kept as the last entry of
+// children and flagged by wildChild.
+type node struct {
+	path      string
+	wildChild bool
+	nType     nodeType
+	indices   string
+	children  []*node
+	handler   Handler
+}
+
+// addRoute inserts handler for path into the tree rooted at n, splitting
+// or creating nodes as required. It panics if path conflicts with an
+// already registered route, e.g. two different param names at the same
+// position, or a route being registered twice.
+func (n *node) addRoute(path string, handler Handler) {
+	fullPath := path
+
+	if n.path == "" && len(n.children) == 0 {
+		n.insertChild(path, fullPath, handler)
+		return
+	}
+
+walk:
+	for {
+		i := longestCommonPrefix(path, n.path)
+
+		// split edge if the paths diverge before the end of n.path
+		if i < len(n.path) {
+			child := node{
+				path:      n.path[i:],
+				wildChild: n.wildChild,
+				nType:     static,
+				indices:   n.indices,
+				children:  n.children,
+				handler:   n.handler,
+			}
+
+			n.children = []*node{&child}
+			n.indices = string(n.path[i])
+			n.path = path[:i]
+			n.handler = nil
+			n.wildChild = false
+		}
+
+		if i < len(path) {
+			path = path[i:]
+			c := path[0]
+
+			// slash after a param segment
+			if n.nType == param && c == '/' && len(n.children) == 1 {
+				n = n.children[0]
+				continue walk
+			}
+
+			if c == ':' || c == '*' {
+				// a wildcard child, if any, is always the last entry in
+				// n.children (see addChild) alongside any static siblings,
+				// so a new wildcard segment must merge with it rather than
+				// replace n.children wholesale.
+				if n.wildChild {
+					n = n.children[len(n.children)-1]
+					if len(path) >= len(n.path) && n.path == path[:len(n.path)] &&
+						(len(n.path) >= len(path) || path[len(n.path)] == '/') {
+						continue walk
+					}
+					panic("'" + path + "' in new path '" + fullPath +
+						"' conflicts with existing wildcard '" + n.path + "'")
+				}
+				n.insertChild(path, fullPath, handler)
+				return
+			}
+
+			for idx, max := 0, len(n.indices); idx < max; idx++ {
+				if c == n.indices[idx] {
+					n = n.children[idx]
+					continue walk
+				}
+			}
+
+			// a *catchAll matches the entire remainder of the path, so a
+			// static sibling next to one would be unreachable (or would
+			// silently shadow it) rather than genuinely coexist the way a
+			// static route and a :param route can.
+			if n.wildChild {
+				if last := n.children[len(n.children)-1]; last.nType == catchAll {
+					panic("'" + path + "' in new path '" + fullPath +
+						"' conflicts with existing wildcard '" + last.path + "'")
+				}
+			}
+
+			n.indices += string(c)
+			child := &node{}
+			n.addChild(child)
+			n = child
+			n.insertChild(path, fullPath, handler)
+			return
+		}
+
+		if n.handler != nil {
+			panic("a route is already registered for path '" + fullPath + "'")
+		}
+		n.handler = handler
+		return
+	}
+}
+
+// insertChild creates the remaining nodes for path below n, splitting
+// path on its next wildcard segment (if any) and recursing until the
+// whole path has been consumed.
+func (n *node) insertChild(path, fullPath string, handler Handler) {
+	for {
+		wildcard, i, valid := findWildcard(path)
+		if i < 0 {
+			break
+		}
+
+		if !valid {
+			panic("only one wildcard per path segment is allowed, has: '" +
+				wildcard + "' in path '" + fullPath + "'")
+		}
+
+		if len(wildcard) < 2 {
+			panic("wildcards must be named with a non-empty name in path '" + fullPath + "'")
+		}
+
+		if wildcard[0] == ':' {
+			if i > 0 {
+				n.path = path[:i]
+				path = path[i:]
+			}
+
+			child := &node{
+				nType: param,
+				path:  wildcard,
+			}
+			n.addChild(child)
+			n.wildChild = true
+			n = child
+
+			if len(wildcard) < len(path) {
+				path = path[len(wildcard):]
+				child := &node{}
+				n.children = append(n.children, child)
+				n = child
+				continue
+			}
+
+			n.handler = handler
+			return
+		}
+
+		// catch-all: only allowed as the last segment of the path
+		if i+len(wildcard) != len(path) {
+			panic("catch-all routes are only allowed at the end of the path in path '" + fullPath + "'")
+		}
+
+		var childPath string
+		if i == 0 {
+			// the '/' preceding the catch-all isn't in path: an earlier
+			// split already absorbed it into n.path (the catch-all is a
+			// sibling of an existing static child, e.g. "/files/readme"
+			// registered before "/files/*rest"). n.path must still end in
+			// it, and no other sibling may already occupy this position.
+			if n.path == "" || n.path[len(n.path)-1] != '/' {
+				panic("no / before catch-all in path '" + fullPath + "'")
+			}
+			if len(n.indices) > 0 {
+				panic("'" + wildcard + "' in new path '" + fullPath +
+					"' conflicts with an existing route sharing its prefix")
+			}
+			childPath = "/" + path
+		} else {
+			// step back onto the '/' so it stays part of the captured
+			// value, matching a request path ending right at the mount
+			// point (e.g. "/files/*filepath" captures "/" for a request
+			// to "/files/").
+			i--
+			if path[i] != '/' {
+				panic("no / before catch-all in path '" + fullPath + "'")
+			}
+			n.path = path[:i]
+			childPath = path[i:]
+		}
+
+		child := &node{
+			nType:   catchAll,
+			path:    childPath,
+			handler: handler,
+		}
+		n.addChild(child)
+		n.wildChild = true
+		return
+	}
+
+	n.path = path
+	n.handler = handler
+}
+
+// addChild appends child to n.children, keeping an existing wildcard
+// child (:param or *catchAll) last so that static children continue to
+// precede it — static edges are matched via indices before the wildcard
+// fallback is tried, letting a static route and a wildcard route coexist
+// at the same position (e.g. "/users/new" next to "/users/:id").
+func (n *node) addChild(child *node) {
+	if n.wildChild && len(n.children) > 0 {
+		last := n.children[len(n.children)-1]
+		n.children = append(append(n.children[:len(n.children)-1:len(n.children)-1], child), last)
+		return
+	}
+	n.children = append(n.children, child)
+}
+
+// getValue walks the tree for path and returns the matched handler and
+// its captured params, or a nil handler if no route matches.
+func (n *node) getValue(path string) (handler Handler, params Params) {
+walk:
+	for {
+		prefix := n.path
+		if len(path) > len(prefix) {
+			if path[:len(prefix)] != prefix {
+				return nil, nil
+			}
+			path = path[len(prefix):]
+
+			c := path[0]
+			for idx, max := 0, len(n.indices); idx < max; idx++ {
+				if c == n.indices[idx] {
+					n = n.children[idx]
+					continue walk
+				}
+			}
+
+			if !n.wildChild {
+				return nil, nil
+			}
+
+			n = n.children[len(n.children)-1]
+			switch n.nType {
+			case param:
+				end := 0
+				for end < len(path) && path[end] != '/' {
+					end++
+				}
+
+				params = append(params, Param{
+					Key:   n.path[1:],
+					Value: path[:end],
+				})
+
+				if end < len(path) {
+					if len(n.children) == 0 {
+						return nil, nil
+					}
+					path = path[end:]
+					n = n.children[0]
+					continue walk
+				}
+				return n.handler, params
+
+			case catchAll:
+				params = append(params, Param{
+					Key:   n.path[2:],
+					Value: path,
+				})
+				return n.handler, params
+
+			default:
+				return nil, nil
+			}
+		}
+
+		if path == prefix {
+			return n.handler, params
+		}
+		return nil, nil
+	}
+}
+
+// walk visits every node of the subtree rooted at n, reconstructing the
+// full path registered for each node that carries a handler (path is the
+// concatenation of all path fragments from the tree's root down to n) and
+// calling fn with it. It is used by Router.Mount to re-register a
+// subrouter's routes, prefixed, onto another Router's trees.
+func (n *node) walk(path string, fn func(path string, handler Handler)) {
+	if n.nType == catchAll && len(path) > 0 && path[len(path)-1] == '/' {
+		// the catch-all was inserted after its '/' had already become part
+		// of the parent's path (see insertChild), so n.path repeats it.
+		path += n.path[1:]
+	} else {
+		path += n.path
+	}
+	if n.handler != nil {
+		fn(path, n.handler)
+	}
+	for _, child := range n.children {
+		child.walk(path, fn)
+	}
+}
+
+// longestCommonPrefix returns the number of leading bytes shared by a and b.
+func longestCommonPrefix(a, b string) int {
+	i, max := 0, len(a)
+	if len(b) < max {
+		max = len(b)
+	}
+	for i < max && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// findWildcard searches path for the next ':param' or '*catchAll'
+// segment. It returns the wildcard text, its start index (or -1 if none
+// was found), and whether it is validly formed (a single wildcard marker
+// per segment).
+func findWildcard(path string) (wildcard string, i int, valid bool) {
+	for start, c := range []byte(path) {
+		if c != ':' && c != '*' {
+			continue
+		}
+
+		valid = true
+		for end, c := range []byte(path[start+1:]) {
+			switch c {
+			case '/':
+				return path[start : start+1+end], start, valid
+			case ':', '*':
+				valid = false
+			}
+		}
+		return path[start:], start, valid
+	}
+	return "", -1, false
+}
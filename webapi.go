@@ -2,8 +2,9 @@ package webapi
 
 import (
 	"net/http"
-
-	pathToRegexp "github.com/soongo/path-to-regexp"
+	"net/url"
+	"sort"
+	"strings"
 )
 
 // HandlerFunc is the definition of any Handler of this Web API framework
@@ -16,7 +17,24 @@ type HandlerFunc func(w http.ResponseWriter, r *ParsedRequest, next func() Handl
 // contains the parsed PathParams.
 type ParsedRequest struct {
 	pathParams map[string]string
-	request    *http.Request
+	// Params holds the same path parameters as pathParams, in the order
+	// they appear in the matched route, for callers that prefer the
+	// typed Params/ByName API over the map.
+	Params  Params
+	request *http.Request
+
+	queryValues url.Values
+	formParsed  bool
+
+	// MaxBodyBytes caps the number of bytes DecodeJSON and DecodeForm
+	// will read from the request body. Zero means no limit.
+	MaxBodyBytes int64
+}
+
+// Request returns the underlying *http.Request for r, giving middleware
+// and handlers access to headers, method, remote address and the like.
+func (r *ParsedRequest) Request() *http.Request {
+	return r.request
 }
 
 // Handler is an interface that defines any request handler of this Framework.
@@ -92,77 +110,234 @@ func NewNativeHandler(handler http.Handler) Handler {
 	)
 }
 
-// routeConig is an internal type that defines a Route Configuration.
-type routeConfig struct {
-	match   func(string) (*pathToRegexp.MatchResult, error)
-	handler Handler
-}
-
-func (cfg *routeConfig) Match(route string) (bool, map[string]string) {
-	res, err := cfg.match(route)
-	if err != nil || res == nil {
-		return false, nil
+// prependHandler builds a Handler chain that runs fns, in order, before
+// tail. It is used by Router.Group and Router.Mount to prepend a group's
+// middleware in front of every handler registered inside it.
+func prependHandler(fns []HandlerFunc, tail Handler) Handler {
+	if len(fns) == 0 {
+		return tail
 	}
 
-	ret := make(map[string]string)
-	for k, v := range res.Params {
-		ret[k.(string)] = v.(string)
+	first := &defaultHandler{fn: fns[0]}
+	cur := first
+	for _, fn := range fns[1:] {
+		next := &defaultHandler{fn: fn}
+		cur.next = next
+		cur = next
 	}
-	return true, ret
+	cur.next = tail
+	return first
 }
 
-func (cfg *routeConfig) Handle(w http.ResponseWriter, r *ParsedRequest) {
-	cfg.handler.HandleAll(w, r)
+// Router is a type used to route HTTP Requests to a specific handler. Each
+// HTTP method owns its own radix tree (see node in tree.go), which is
+// capable of parsing static, `:param` and `*catchall` path segments.
+// Looking up a static path allocates nothing; a match that captures
+// parameters allocates the Params slice that holds them.
+type Router struct {
+	handlers map[string]*node
+	fallback Handler
+	mounts   *[]mountPoint
+
+	// prefix is prepended to every path registered through this Router,
+	// set by Group for the routes registered on the returned subrouter.
+	prefix string
+	// middleware is prepended, in order, to every handler registered
+	// through this Router, inherited and extended by Group.
+	middleware []HandlerFunc
+
+	// HandleMethodNotAllowed, when true, makes ServeHTTP respond with
+	// 405 Method Not Allowed (and an Allow header) instead of falling
+	// back when the path matches a route under a different method.
+	HandleMethodNotAllowed bool
+	// HandleOPTIONS, when true, makes ServeHTTP answer OPTIONS requests
+	// automatically with a 200 and an Allow header, unless an OPTIONS
+	// handler was explicitly registered for the path.
+	HandleOPTIONS bool
+	// MethodNotAllowed is called for 405 responses when
+	// HandleMethodNotAllowed is enabled. If nil, a default handler
+	// writing "405 method not allowed" is used.
+	MethodNotAllowed Handler
 }
 
-// Router is a type used to route HTTP Requests to a specific handler. The
-// router is also capable of parsing path params if the routeConfig's regex
-// supports named capture groups.
-type Router struct {
-	handlers map[string][]*routeConfig
+// mountPoint records a subrouter fallback spliced in via Router.Mount, so
+// ServeHTTP can use it instead of the parent fallback when a request
+// falls under the mounted prefix but matches no route inside it.
+type mountPoint struct {
+	prefix   string
 	fallback Handler
 }
 
-// Handle registers a handler for a given request type.
-func (router *Router) Handle(method, matcher string, handler Handler) {
-	match := pathToRegexp.MustMatch(matcher, &pathToRegexp.Options{Decode: func(str string, token interface{}) (string, error) {
-		return pathToRegexp.DecodeURIComponent(str)
-	}}) // todo panics
+// defaultMethodNotAllowed is used by ServeHTTP whenever
+// Router.MethodNotAllowed is not set.
+var defaultMethodNotAllowed = NewErrorHandler(http.StatusMethodNotAllowed, "405 method not allowed")
+
+// noBodyResponseWriter discards the response body, used to answer HEAD
+// requests through a GET handler without sending content.
+type noBodyResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *noBodyResponseWriter) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+// Handle registers a handler for a given request method and path. The
+// path may contain named `:param` segments and a single trailing
+// `*catchall` segment. Handle panics if the path conflicts with an
+// already registered route. If this Router was created via Group, the
+// group's prefix and middleware chain are applied first.
+func (router *Router) Handle(method, path string, handler Handler) {
+	if len(router.middleware) > 0 {
+		handler = prependHandler(router.middleware, handler)
+	}
+
+	root := router.handlers[method]
+	if root == nil {
+		root = &node{}
+		router.handlers[method] = root
+	}
+	root.addRoute(router.prefix+path, handler)
+}
+
+// Group returns a subrouter that registers its routes under prefix and
+// runs middleware, in order, after the parent's own middleware chain and
+// in front of every handler registered on it. The returned Router shares
+// the parent's route trees, fallback and mount points, so routes
+// registered on it are served by the parent's ServeHTTP.
+func (router *Router) Group(prefix string, middleware ...HandlerFunc) *Router {
+	chain := make([]HandlerFunc, 0, len(router.middleware)+len(middleware))
+	chain = append(chain, router.middleware...)
+	chain = append(chain, middleware...)
+
+	return &Router{
+		handlers:               router.handlers,
+		fallback:               router.fallback,
+		mounts:                 router.mounts,
+		prefix:                 router.prefix + prefix,
+		middleware:             chain,
+		HandleMethodNotAllowed: router.HandleMethodNotAllowed,
+		HandleOPTIONS:          router.HandleOPTIONS,
+		MethodNotAllowed:       router.MethodNotAllowed,
+	}
+}
+
+// Mount splices sub's registered routes into router under prefix,
+// preserving sub's own middleware chain. When a request path falls
+// under prefix but matches no route inside the mounted subtree, sub's
+// fallback is used instead of router's own fallback.
+func (router *Router) Mount(prefix string, sub *Router) {
+	for method, root := range sub.handlers {
+		method := method
+		root.walk("", func(path string, handler Handler) {
+			router.Handle(method, prefix+path, handler)
+		})
+	}
 
-	router.handlers[method] = append(router.handlers[method], &routeConfig{
-		match: match,
+	*router.mounts = append(*router.mounts, mountPoint{
+		prefix:   router.prefix + prefix,
+		fallback: sub.fallback,
 	})
 }
 
 // ServeHTTP implements the net/http Handler interface so that the Router
 // can be used as native net/http Handler.
 func (router *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if router.handlers[r.Method] == nil {
-		router.fallback.HandleAll(w, &ParsedRequest{
-			request: r,
-		})
-		return
-	}
-	for _, cfg := range router.handlers[r.Method] {
-		matches, pathParams := cfg.Match(r.URL.Path)
-		if matches {
-			parsed := &ParsedRequest{
-				pathParams: pathParams,
-				request:    r,
+	path := r.URL.Path
+
+	if root := router.handlers[r.Method]; root != nil {
+		if handler, params := root.getValue(path); handler != nil {
+			router.serve(w, r, handler, params)
+			return
+		}
+	}
+
+	if r.Method == http.MethodHead {
+		if root := router.handlers[http.MethodGet]; root != nil {
+			if handler, params := root.getValue(path); handler != nil {
+				router.serve(&noBodyResponseWriter{w}, r, handler, params)
+				return
+			}
+		}
+	}
+
+	if allowed := router.allowed(path, r.Method); len(allowed) > 0 {
+		sort.Strings(allowed)
+		allow := strings.Join(allowed, ", ")
+
+		if r.Method == http.MethodOptions && router.HandleOPTIONS {
+			w.Header().Set("Allow", allow)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if router.HandleMethodNotAllowed {
+			w.Header().Set("Allow", allow)
+			handler := router.MethodNotAllowed
+			if handler == nil {
+				handler = defaultMethodNotAllowed
 			}
-			cfg.handler.HandleAll(w, parsed)
+			handler.HandleAll(w, &ParsedRequest{request: r})
 			return
 		}
 	}
-	router.fallback.HandleAll(w, &ParsedRequest{
+
+	router.fallbackFor(path).HandleAll(w, &ParsedRequest{
 		request: r,
 	})
 }
 
+// fallbackFor returns the fallback that applies to path: the most
+// specific mount fallback whose prefix contains path, or the router's
+// own fallback if no mount matches.
+func (router *Router) fallbackFor(path string) Handler {
+	fallback := router.fallback
+	bestLen := -1
+	for _, m := range *router.mounts {
+		if strings.HasPrefix(path, m.prefix) && len(m.prefix) > bestLen {
+			fallback = m.fallback
+			bestLen = len(m.prefix)
+		}
+	}
+	return fallback
+}
+
+// serve builds the ParsedRequest for a matched route and runs handler.
+func (router *Router) serve(w http.ResponseWriter, r *http.Request, handler Handler, params Params) {
+	var pathParams map[string]string
+	if len(params) > 0 {
+		pathParams = make(map[string]string, len(params))
+		for _, p := range params {
+			pathParams[p.Key] = p.Value
+		}
+	}
+	handler.HandleAll(w, &ParsedRequest{
+		pathParams: pathParams,
+		Params:     params,
+		request:    r,
+	})
+}
+
+// allowed returns the HTTP methods, other than skip, for which some route
+// matches path. It is used to build 405 and OPTIONS responses.
+func (router *Router) allowed(path, skip string) []string {
+	var methods []string
+	for method, root := range router.handlers {
+		if method == skip {
+			continue
+		}
+		if handler, _ := root.getValue(path); handler != nil {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
 // NewRouter creates a new Router.
 func NewRouter(fallback Handler) *Router {
 	return &Router{
-		handlers: make(map[string][]*routeConfig),
+		handlers: make(map[string]*node),
 		fallback: fallback,
+		mounts:   &[]mountPoint{},
 	}
 }
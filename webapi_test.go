@@ -211,3 +211,380 @@ func TestRouter(t *testing.T) {
 		}
 	}
 }
+
+func TestRouterCatchAll(t *testing.T) {
+	log.Println("Test Router Catch All")
+	fallback404 := NewErrorHandler(http.StatusNotFound, "404 not found")
+	staticHandler := NewHandler(func(w http.ResponseWriter, r *ParsedRequest, next func() Handler) Handler {
+		w.Write([]byte(r.Params.ByName("filepath")))
+		return next()
+	})
+
+	router := NewRouter(fallback404)
+	router.Handle(http.MethodGet, "/static/*filepath", staticHandler)
+
+	cases := []string{"/static/foo.js", "/static/a/b.js"}
+	for _, path := range cases {
+		w := newMockResponseWriter()
+		r := newMockRequest(func(req *ParsedRequest) {
+			req.request.Method = http.MethodGet
+			req.request.URL = &url.URL{Path: path}
+		})
+
+		router.ServeHTTP(w, r.request)
+		have, want := w.String(), path[len("/static"):]
+		if have != want {
+			t.Errorf("CatchAll %s: have body %q want %q", path, have, want)
+		}
+	}
+}
+
+func TestRouterCatchAllConflictsWithStaticSibling(t *testing.T) {
+	log.Println("Test Router Catch All Conflicts With Static Sibling")
+	noop := NewHandler(func(w http.ResponseWriter, r *ParsedRequest, next func() Handler) Handler {
+		return next()
+	})
+
+	assertPanics := func(t *testing.T, register func(router *Router)) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected Handle to panic on the conflicting route")
+			}
+		}()
+		register(NewRouter(NewErrorHandler(http.StatusNotFound, "404 not found")))
+	}
+
+	t.Run("catch-all registered first", func(t *testing.T) {
+		assertPanics(t, func(router *Router) {
+			router.Handle(http.MethodGet, "/files/*rest", noop)
+			router.Handle(http.MethodGet, "/files/readme", noop)
+		})
+	})
+
+	t.Run("static registered first", func(t *testing.T) {
+		assertPanics(t, func(router *Router) {
+			router.Handle(http.MethodGet, "/files/readme", noop)
+			router.Handle(http.MethodGet, "/files/*rest", noop)
+		})
+	})
+}
+
+func TestRouterStaticAndWildcardSiblings(t *testing.T) {
+	log.Println("Test Router Static And Wildcard Siblings")
+	fallback404 := NewErrorHandler(http.StatusNotFound, "404 not found")
+	staticMock := "static"
+	paramHandler := NewHandler(func(w http.ResponseWriter, r *ParsedRequest, next func() Handler) Handler {
+		w.Write([]byte(r.Params.ByName("id")))
+		return next()
+	})
+	staticHandler := NewHandler(func(w http.ResponseWriter, r *ParsedRequest, next func() Handler) Handler {
+		w.Write([]byte(staticMock))
+		return next()
+	})
+
+	run := func(t *testing.T, router *Router) {
+		// the static route must win over the wildcard for its exact path
+		{
+			w := newMockResponseWriter()
+			r := newMockRequest(func(req *ParsedRequest) {
+				req.request.Method = http.MethodGet
+				req.request.URL = &url.URL{Path: "/users/new"}
+			})
+			router.ServeHTTP(w, r.request)
+			if have, want := w.String(), staticMock; have != want {
+				t.Errorf("/users/new: have body %q want %q", have, want)
+			}
+		}
+
+		// any other value still falls through to the wildcard
+		{
+			w := newMockResponseWriter()
+			r := newMockRequest(func(req *ParsedRequest) {
+				req.request.Method = http.MethodGet
+				req.request.URL = &url.URL{Path: "/users/42"}
+			})
+			router.ServeHTTP(w, r.request)
+			if have, want := w.String(), "42"; have != want {
+				t.Errorf("/users/42: have body %q want %q", have, want)
+			}
+		}
+	}
+
+	t.Run("static registered first", func(t *testing.T) {
+		router := NewRouter(fallback404)
+		router.Handle(http.MethodGet, "/users/new", staticHandler)
+		router.Handle(http.MethodGet, "/users/:id", paramHandler)
+		run(t, router)
+	})
+
+	t.Run("wildcard registered first", func(t *testing.T) {
+		router := NewRouter(fallback404)
+		router.Handle(http.MethodGet, "/users/:id", paramHandler)
+		router.Handle(http.MethodGet, "/users/new", staticHandler)
+		run(t, router)
+	})
+}
+
+func TestRouterMethodNotAllowed(t *testing.T) {
+	log.Println("Test Router Method Not Allowed")
+	fallback404 := NewErrorHandler(http.StatusNotFound, "404 not found")
+	helloHandler := NewHandler(func(w http.ResponseWriter, r *ParsedRequest, next func() Handler) Handler {
+		w.Write([]byte("hello"))
+		return next()
+	})
+
+	router := NewRouter(fallback404)
+	router.Handle(http.MethodGet, "/hello", helloHandler)
+
+	// disabled: falls back to 404
+	{
+		w := newMockResponseWriter()
+		r := newMockRequest(func(req *ParsedRequest) {
+			req.request.Method = http.MethodPost
+			req.request.URL = &url.URL{Path: "/hello"}
+		})
+
+		router.ServeHTTP(w, r.request)
+		have, want := w.status, http.StatusNotFound
+		if have != want {
+			t.Errorf("Disabled: have status %d want %d", have, want)
+		}
+	}
+
+	// enabled: 405 with Allow header
+	{
+		router.HandleMethodNotAllowed = true
+		w := newMockResponseWriter()
+		r := newMockRequest(func(req *ParsedRequest) {
+			req.request.Method = http.MethodPost
+			req.request.URL = &url.URL{Path: "/hello"}
+		})
+
+		router.ServeHTTP(w, r.request)
+		have, want := w.status, http.StatusMethodNotAllowed
+		if have != want {
+			t.Errorf("Enabled: have status %d want %d", have, want)
+		}
+		if allow := w.Header().Get("Allow"); allow != http.MethodGet {
+			t.Errorf("Enabled: have Allow header %q want %q", allow, http.MethodGet)
+		}
+	}
+}
+
+func TestRouterHeadFallsBackToGet(t *testing.T) {
+	log.Println("Test Router Head Falls Back To Get")
+	fallback404 := NewErrorHandler(http.StatusNotFound, "404 not found")
+	mockData := "hello"
+	helloHandler := NewHandler(func(w http.ResponseWriter, r *ParsedRequest, next func() Handler) Handler {
+		w.Write([]byte(mockData))
+		return next()
+	})
+
+	router := NewRouter(fallback404)
+	router.Handle(http.MethodGet, "/hello", helloHandler)
+
+	w := newMockResponseWriter()
+	r := newMockRequest(func(req *ParsedRequest) {
+		req.request.Method = http.MethodHead
+		req.request.URL = &url.URL{Path: "/hello"}
+	})
+
+	router.ServeHTTP(w, r.request)
+	have, want := w.status, http.StatusOK
+	if have != want {
+		t.Errorf("have status %d want %d", have, want)
+	}
+	if len(w.Data()) != 0 {
+		t.Errorf("HEAD response should have no body, got %q", w.String())
+	}
+}
+
+func TestRouterOptions(t *testing.T) {
+	log.Println("Test Router Options")
+	fallback404 := NewErrorHandler(http.StatusNotFound, "404 not found")
+	helloHandler := NewHandler(func(w http.ResponseWriter, r *ParsedRequest, next func() Handler) Handler {
+		w.Write([]byte("hello"))
+		return next()
+	})
+
+	router := NewRouter(fallback404)
+	router.HandleOPTIONS = true
+	router.Handle(http.MethodGet, "/hello", helloHandler)
+
+	w := newMockResponseWriter()
+	r := newMockRequest(func(req *ParsedRequest) {
+		req.request.Method = http.MethodOptions
+		req.request.URL = &url.URL{Path: "/hello"}
+	})
+
+	router.ServeHTTP(w, r.request)
+	have, want := w.status, http.StatusOK
+	if have != want {
+		t.Errorf("have status %d want %d", have, want)
+	}
+	if allow := w.Header().Get("Allow"); allow != http.MethodGet {
+		t.Errorf("have Allow header %q want %q", allow, http.MethodGet)
+	}
+}
+
+func TestRouterGroup(t *testing.T) {
+	log.Println("Test Router Group")
+	fallback404 := NewErrorHandler(http.StatusNotFound, "404 not found")
+
+	var order []string
+	mw := func(name string) HandlerFunc {
+		return func(w http.ResponseWriter, r *ParsedRequest, next func() Handler) Handler {
+			order = append(order, name)
+			return next()
+		}
+	}
+
+	usersHandler := NewHandler(func(w http.ResponseWriter, r *ParsedRequest, next func() Handler) Handler {
+		w.Write([]byte("users"))
+		return next()
+	})
+
+	router := NewRouter(fallback404)
+	api := router.Group("/api", mw("outer"))
+	v1 := api.Group("/v1", mw("inner"))
+	v1.Handle(http.MethodGet, "/users", usersHandler)
+
+	w := newMockResponseWriter()
+	r := newMockRequest(func(req *ParsedRequest) {
+		req.request.Method = http.MethodGet
+		req.request.URL = &url.URL{Path: "/api/v1/users"}
+	})
+
+	router.ServeHTTP(w, r.request)
+	have, want := w.String(), "users"
+	if have != want {
+		t.Errorf("have body %q want %q", have, want)
+	}
+	if len(order) != 2 || order[0] != "outer" || order[1] != "inner" {
+		t.Errorf("middleware did not run in the expected order, got %v", order)
+	}
+}
+
+func TestRouterMount(t *testing.T) {
+	log.Println("Test Router Mount")
+	parentFallback := NewErrorHandler(http.StatusNotFound, "parent not found")
+	subFallback := NewErrorHandler(http.StatusNotFound, "sub not found")
+
+	adminHandler := NewHandler(func(w http.ResponseWriter, r *ParsedRequest, next func() Handler) Handler {
+		w.Write([]byte("admin"))
+		return next()
+	})
+
+	sub := NewRouter(subFallback)
+	sub.Handle(http.MethodGet, "/dashboard", adminHandler)
+
+	router := NewRouter(parentFallback)
+	router.Mount("/admin", sub)
+
+	// mounted route is reachable through the parent router
+	{
+		w := newMockResponseWriter()
+		r := newMockRequest(func(req *ParsedRequest) {
+			req.request.Method = http.MethodGet
+			req.request.URL = &url.URL{Path: "/admin/dashboard"}
+		})
+
+		router.ServeHTTP(w, r.request)
+		have, want := w.String(), "admin"
+		if have != want {
+			t.Errorf("have body %q want %q", have, want)
+		}
+	}
+
+	// unmatched path under the mounted prefix uses the sub's fallback
+	{
+		w := newMockResponseWriter()
+		r := newMockRequest(func(req *ParsedRequest) {
+			req.request.Method = http.MethodGet
+			req.request.URL = &url.URL{Path: "/admin/missing"}
+		})
+
+		router.ServeHTTP(w, r.request)
+		have, want := w.String(), "sub not found\n"
+		if have != want {
+			t.Errorf("have body %q want %q", have, want)
+		}
+	}
+
+	// unmatched path outside the mounted prefix uses the parent's fallback
+	{
+		w := newMockResponseWriter()
+		r := newMockRequest(func(req *ParsedRequest) {
+			req.request.Method = http.MethodGet
+			req.request.URL = &url.URL{Path: "/elsewhere"}
+		})
+
+		router.ServeHTTP(w, r.request)
+		have, want := w.String(), "parent not found\n"
+		if have != want {
+			t.Errorf("have body %q want %q", have, want)
+		}
+	}
+}
+
+func TestRouterGroupMountFallback(t *testing.T) {
+	log.Println("Test Router Group Mount Fallback")
+	parentFallback := NewErrorHandler(http.StatusNotFound, "parent not found")
+	subFallback := NewErrorHandler(http.StatusNotFound, "sub not found")
+
+	adminHandler := NewHandler(func(w http.ResponseWriter, r *ParsedRequest, next func() Handler) Handler {
+		w.Write([]byte("admin"))
+		return next()
+	})
+
+	sub := NewRouter(subFallback)
+	sub.Handle(http.MethodGet, "/dashboard", adminHandler)
+
+	router := NewRouter(parentFallback)
+	router.Group("/api").Mount("/admin", sub)
+
+	// mounted route is reachable under the group's prefix
+	{
+		w := newMockResponseWriter()
+		r := newMockRequest(func(req *ParsedRequest) {
+			req.request.Method = http.MethodGet
+			req.request.URL = &url.URL{Path: "/api/admin/dashboard"}
+		})
+
+		router.ServeHTTP(w, r.request)
+		have, want := w.String(), "admin"
+		if have != want {
+			t.Errorf("have body %q want %q", have, want)
+		}
+	}
+
+	// unmatched path under the mounted prefix uses the sub's fallback
+	{
+		w := newMockResponseWriter()
+		r := newMockRequest(func(req *ParsedRequest) {
+			req.request.Method = http.MethodGet
+			req.request.URL = &url.URL{Path: "/api/admin/missing"}
+		})
+
+		router.ServeHTTP(w, r.request)
+		have, want := w.String(), "sub not found\n"
+		if have != want {
+			t.Errorf("have body %q want %q", have, want)
+		}
+	}
+
+	// unmatched path outside the mounted prefix uses the parent's fallback
+	{
+		w := newMockResponseWriter()
+		r := newMockRequest(func(req *ParsedRequest) {
+			req.request.Method = http.MethodGet
+			req.request.URL = &url.URL{Path: "/elsewhere"}
+		})
+
+		router.ServeHTTP(w, r.request)
+		have, want := w.String(), "parent not found\n"
+		if have != want {
+			t.Errorf("have body %q want %q", have, want)
+		}
+	}
+}